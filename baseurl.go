@@ -0,0 +1,131 @@
+package httpcli
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// WithBaseURL constructor option stores a base URL that relative paths
+// passed to Get/Post/etc. are resolved against. rawurl must be an absolute
+// URL; it panics otherwise, consistent with the other eagerly-validated
+// options.
+func WithBaseURL(rawurl string) RequestOption {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		panic(fmt.Sprintf("httpcli: invalid base url: %s", err))
+	}
+
+	if !u.IsAbs() {
+		panic(fmt.Sprintf("httpcli: base url is not absolute: %s", rawurl))
+	}
+
+	return func(meta *requestMeta) {
+		meta.baseURL = u
+	}
+}
+
+// WithQuery request option merges v into the final request URL's query string.
+func WithQuery(v url.Values) RequestOption {
+	return func(meta *requestMeta) {
+		if meta.query == nil {
+			meta.query = make(url.Values, len(v))
+		}
+
+		for k, vv := range v {
+			meta.query[k] = append(meta.query[k], vv...)
+		}
+	}
+}
+
+// AddQuery request option adds a single key/value pair to the final request URL's query string.
+func AddQuery(key, value string) RequestOption {
+	return func(meta *requestMeta) {
+		if meta.query == nil {
+			meta.query = make(url.Values, 1)
+		}
+
+		meta.query.Add(key, value)
+	}
+}
+
+// WithPathParams request option substitutes {name} placeholders in the
+// request path with the (URL-escaped) given values.
+func WithPathParams(params map[string]string) RequestOption {
+	return func(meta *requestMeta) {
+		if meta.pathParams == nil {
+			meta.pathParams = make(map[string]string, len(params))
+		}
+
+		for k, v := range params {
+			meta.pathParams[k] = v
+		}
+	}
+}
+
+func cloneQuery(v url.Values) url.Values {
+	if v == nil {
+		return nil
+	}
+
+	newV := make(url.Values, len(v))
+
+	for k, vv := range v {
+		newV[k] = append([]string(nil), vv...)
+	}
+
+	return newV
+}
+
+func clonePathParams(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+
+	newM := make(map[string]string, len(m))
+
+	for k, v := range m {
+		newM[k] = v
+	}
+
+	return newM
+}
+
+func substitutePathParams(rawurl string, params map[string]string) string {
+	if len(params) == 0 {
+		return rawurl
+	}
+
+	for name, value := range params {
+		rawurl = strings.ReplaceAll(rawurl, "{"+name+"}", url.PathEscape(value))
+	}
+
+	return rawurl
+}
+
+// buildRequestURL substitutes path params, resolves rawurl against base
+// (when rawurl is not already absolute) and merges query into the result.
+func buildRequestURL(rawurl string, base *url.URL, pathParams map[string]string, query url.Values) (string, error) {
+	rawurl = substitutePathParams(rawurl, pathParams)
+
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return "", fmt.Errorf("parse url:%w", err)
+	}
+
+	if base != nil && !u.IsAbs() {
+		u = base.ResolveReference(u)
+	}
+
+	if len(query) != 0 {
+		q := u.Query()
+
+		for k, vv := range query {
+			q[k] = append(q[k], vv...)
+		}
+
+		u.RawQuery = q.Encode()
+	}
+
+	return u.String(), nil
+}
@@ -0,0 +1,103 @@
+package httpcli
+
+import (
+	"net/url"
+	"testing"
+)
+
+func Test_buildRequestURL(t *testing.T) {
+	base, err := url.Parse("https://api.example.com/v1/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type args struct {
+		rawurl     string
+		base       *url.URL
+		pathParams map[string]string
+		query      url.Values
+	}
+
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			"absolute url ignores base",
+			args{rawurl: "https://other.example.com/foo", base: base},
+			"https://other.example.com/foo",
+			false,
+		},
+		{
+			"relative path resolved against base",
+			args{rawurl: "users/1", base: base},
+			"https://api.example.com/v1/users/1",
+			false,
+		},
+		{
+			"path params substituted",
+			args{
+				rawurl:     "users/{id}",
+				base:       base,
+				pathParams: map[string]string{"id": "a b"},
+			},
+			"https://api.example.com/v1/users/a%20b",
+			false,
+		},
+		{
+			"query merged",
+			args{
+				rawurl: "https://other.example.com/foo?a=1",
+				query:  url.Values{"b": []string{"2"}},
+			},
+			"https://other.example.com/foo?a=1&b=2",
+			false,
+		},
+		{
+			"invalid url",
+			args{rawurl: "://bad"},
+			"",
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildRequestURL(tt.args.rawurl, tt.args.base, tt.args.pathParams, tt.args.query)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("buildRequestURL() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if got != tt.want {
+				t.Errorf("buildRequestURL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_requestOptions_baseURL(t *testing.T) {
+	cli := New("test", WithDoer(nil), WithBaseURL("https://api.example.com/v1/"))
+	rmt := cli.defaultRequestMeta
+
+	if rmt.baseURL == nil || rmt.baseURL.String() != "https://api.example.com/v1/" {
+		t.Errorf("rmt.baseURL = %v, want https://api.example.com/v1/", rmt.baseURL)
+	}
+
+	opt := []RequestOption{AddQuery("a", "1"), WithQuery(url.Values{"b": []string{"2"}}),
+		WithPathParams(map[string]string{"id": "1"})}
+
+	for _, o := range opt {
+		o(&rmt)
+	}
+
+	if rmt.query.Get("a") != "1" || rmt.query.Get("b") != "2" {
+		t.Errorf("rmt.query = %v", rmt.query)
+	}
+
+	if rmt.pathParams["id"] != "1" {
+		t.Errorf("rmt.pathParams = %v", rmt.pathParams)
+	}
+}
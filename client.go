@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 	"unsafe"
 )
 
@@ -60,11 +61,50 @@ func (e ErrWithResponseData) Headers() http.Header {
 
 type (
 	// Decoder represent decoder function
+	//
+	// Deprecated: use DecoderFunc, which receives a context.Context so
+	// codecs can honor cancellation and read values set by Before hooks.
 	Decoder func(io.Reader, interface{}) error
 	// Encoder represent encoder function
+	//
+	// Deprecated: use EncoderFunc, which receives a context.Context so
+	// codecs can honor cancellation and read values set by Before hooks.
 	Encoder func(io.Writer, interface{}) error
+
+	// DecoderFunc represent a context-aware decoder function
+	DecoderFunc func(context.Context, io.Reader, interface{}) error
+	// EncoderFunc represent a context-aware encoder function
+	EncoderFunc func(context.Context, io.Writer, interface{}) error
+
+	// StreamDecoder receives the raw *http.Response instead of its body, so
+	// it can implement chunked/NDJSON/SSE consumption where result is a
+	// channel or callback rather than a single value to unmarshal once. It
+	// owns resp.Body's lifetime and is responsible for closing it.
+	StreamDecoder func(context.Context, *http.Response, interface{}) error
 )
 
+// adaptEncoder turns a legacy Encoder into an EncoderFunc that ignores ctx.
+func adaptEncoder(enc Encoder) EncoderFunc {
+	if enc == nil {
+		return nil
+	}
+
+	return func(_ context.Context, w io.Writer, v interface{}) error {
+		return enc(w, v)
+	}
+}
+
+// adaptDecoder turns a legacy Decoder into a DecoderFunc that ignores ctx.
+func adaptDecoder(dec Decoder) DecoderFunc {
+	if dec == nil {
+		return nil
+	}
+
+	return func(_ context.Context, r io.Reader, v interface{}) error {
+		return dec(r, v)
+	}
+}
+
 // JSONDecoder decode data from r to v as json (used by default)
 func JSONDecoder(r io.Reader, v interface{}) error {
 	err := json.NewDecoder(r).Decode(v)
@@ -164,8 +204,45 @@ type (
 	Doer interface {
 		Do(*http.Request) (*http.Response, error)
 	}
+
+	// RequestFunc is executed on the outgoing *http.Request after it is built
+	// but before it is handed to the Doer. It may return a new context which
+	// is propagated to the following hooks, the Doer and any ResponseFunc.
+	RequestFunc func(context.Context, *http.Request) context.Context
+
+	// ResponseFunc is executed on the *http.Response returned by the Doer
+	// before status-code checks and body decoding happen. It may return a
+	// new context which is propagated to the following hooks and to the
+	// context sink installed via WithContextSink.
+	ResponseFunc func(context.Context, *http.Response) context.Context
 )
 
+// WithBefore request option to add hooks run before the request is sent
+func WithBefore(before ...RequestFunc) RequestOption {
+	return func(meta *requestMeta) {
+		meta.before = append(meta.before, before...)
+	}
+}
+
+// WithAfter request option to add hooks run after the response is received
+func WithAfter(after ...ResponseFunc) RequestOption {
+	return func(meta *requestMeta) {
+		meta.after = append(meta.after, after...)
+	}
+}
+
+// WithContextSink request option to retrieve the final hook context after
+// Get/Post/etc. return. sink must not be nil.
+func WithContextSink(sink *context.Context) RequestOption {
+	if sink == nil {
+		panic("sink should not be nil")
+	}
+
+	return func(meta *requestMeta) {
+		meta.ctxSink = sink
+	}
+}
+
 // WithDoer request option to change default Doer
 func WithDoer(d Doer) RequestOption {
 	return func(meta *requestMeta) {
@@ -232,26 +309,72 @@ func ExpectedCodes(codes ...int) RequestOption {
 }
 
 // WithDecoder request option to change request decoder
+//
+// Deprecated: use WithDecoderFunc for a context-aware decoder.
 func WithDecoder(v Decoder) RequestOption {
 	return func(meta *requestMeta) {
-		meta.dec = v
+		meta.dec = adaptDecoder(v)
+		meta.customDec = true
 	}
 }
 
 // WithEncoder request option to change request encoder
+//
+// Deprecated: use WithEncoderFunc for a context-aware encoder.
 func WithEncoder(v Encoder) RequestOption {
+	return func(meta *requestMeta) {
+		meta.enc = adaptEncoder(v)
+		meta.customEnc = true
+	}
+}
+
+// WithDecoderFunc request option to change request decoder
+func WithDecoderFunc(v DecoderFunc) RequestOption {
+	return func(meta *requestMeta) {
+		meta.dec = v
+		meta.customDec = true
+	}
+}
+
+// WithEncoderFunc request option to change request encoder
+func WithEncoderFunc(v EncoderFunc) RequestOption {
 	return func(meta *requestMeta) {
 		meta.enc = v
+		meta.customEnc = true
+	}
+}
+
+// WithStreamDecoder request option installs a StreamDecoder that receives
+// the raw *http.Response instead of going through Decoder/DecoderFunc. When
+// set, Client.request hands resp to it directly and does not close
+// resp.Body itself - the StreamDecoder owns that lifetime, which lets it
+// implement chunked/NDJSON/SSE consumption.
+func WithStreamDecoder(v StreamDecoder) RequestOption {
+	return func(meta *requestMeta) {
+		meta.streamDec = v
 	}
 }
 
 type requestMeta struct {
-	doer          Doer
-	headers       http.Header
-	okCodes       codeSet
-	customOkCodes bool
-	enc           Encoder
-	dec           Decoder
+	doer           Doer
+	headers        http.Header
+	okCodes        codeSet
+	customOkCodes  bool
+	enc            EncoderFunc
+	dec            DecoderFunc
+	customEnc      bool
+	customDec      bool
+	streamDec      StreamDecoder
+	before         []RequestFunc
+	after          []ResponseFunc
+	ctxSink        *context.Context
+	retry          *RetryPolicy
+	baseURL        *url.URL
+	query          url.Values
+	pathParams     map[string]string
+	codecs         map[string]Codec
+	autoDecompress bool
+	decompressors  map[string]Decompressor
 }
 
 type codeSet map[int]bool
@@ -290,8 +413,8 @@ func New(name string, opt ...RequestOption) Client {
 		defaultRequestMeta: requestMeta{
 			doer:    &cli,
 			okCodes: defaultOkCodes.Clone(),
-			enc:     JSONEncoder,
-			dec:     JSONDecoder,
+			enc:     adaptEncoder(JSONEncoder),
+			dec:     adaptDecoder(JSONDecoder),
 			headers: h,
 		},
 	}
@@ -303,54 +426,88 @@ func New(name string, opt ...RequestOption) Client {
 	return c
 }
 
-func (c Client) do(req *http.Request, rm requestMeta) (*http.Response, error) {
-	resp, err := rm.doer.Do(req)
+// NewWithDefaults creates a new http client with production-friendly
+// defaults enabled on top of New (currently: transparent gzip/deflate
+// response decompression). Options passed here are applied afterwards and
+// can still override them.
+func NewWithDefaults(name string, opt ...RequestOption) Client {
+	return New(name, append([]RequestOption{WithAutoDecompress(true)}, opt...)...)
+}
+
+// send runs the before hooks, performs the actual Do and runs the after
+// hooks, returning the raw response so callers can inspect the status code
+// (e.g. to decide whether to retry) before it is turned into an error.
+func (c Client) send(ctx context.Context, req *http.Request, rm requestMeta) (context.Context, *http.Response, error) {
+	for _, f := range rm.before {
+		ctx = f(ctx, req)
+	}
+
+	resp, err := rm.doer.Do(req.WithContext(ctx))
 	if err != nil {
-		return nil, fmt.Errorf("do:%w", err)
+		return ctx, nil, fmt.Errorf("do:%w", err)
 	}
 
-	if rm.okCodes[resp.StatusCode] {
-		return resp, nil
+	for _, f := range rm.after {
+		ctx = f(ctx, resp)
 	}
 
+	return ctx, resp, nil
+}
+
+// buildStatusError drains and closes resp.Body and wraps it, together with
+// the status code and headers, into an ErrWithResponseData.
+func buildStatusError(resp *http.Response) error {
 	var buf bytes.Buffer
 
 	baseErr := ErrBadStatus
 
-	_, err = io.Copy(&buf, resp.Body)
+	_, err := io.Copy(&buf, resp.Body)
 	if err != nil {
 		baseErr = fmt.Errorf("%w:copy body:%s", ErrBadStatus, err)
 	}
 
 	_ = resp.Body.Close()
 
-	return nil, fmt.Errorf("do:%w",
+	return fmt.Errorf("do:%w",
 		ErrWithResponseData{
 			baseErr, resp.StatusCode,
 			resp.Header.Clone(), &buf,
 		})
 }
 
-func buildRequestBody(enc Encoder, payload interface{}) (*bytes.Buffer, error) {
+func (c Client) do(ctx context.Context, req *http.Request, rm requestMeta) (context.Context, *http.Response, error) {
+	ctx, resp, err := c.send(ctx, req, rm)
+	if err != nil {
+		return ctx, nil, err
+	}
+
+	if rm.okCodes[resp.StatusCode] {
+		return ctx, resp, nil
+	}
+
+	return ctx, nil, buildStatusError(resp)
+}
+
+func buildRequestBody(ctx context.Context, enc EncoderFunc, payload interface{}) (*bytes.Buffer, error) {
 	var buf bytes.Buffer
 
 	if payload == nil {
 		return &buf, nil
 	}
 
-	if err := enc(&buf, payload); err != nil {
+	if err := enc(ctx, &buf, payload); err != nil {
 		return nil, fmt.Errorf("encode_request:%w", err)
 	}
 
 	return &buf, nil
 }
 
-func parseResponseBody(dec Decoder, body io.Reader, result interface{}) error {
+func parseResponseBody(ctx context.Context, dec DecoderFunc, body io.Reader, result interface{}) error {
 	if result == nil {
 		return nil
 	}
 
-	if err := dec(body, result); err != nil {
+	if err := dec(ctx, body, result); err != nil {
 		return fmt.Errorf("decode_response:%w", err)
 	}
 
@@ -363,11 +520,24 @@ func (c Client) request(ctx context.Context, url, method string, payload,
 
 	if len(opt) != 0 {
 		rm = requestMeta{
-			doer:    c.defaultRequestMeta.doer,
-			headers: c.defaultRequestMeta.headers.Clone(),
-			okCodes: c.defaultRequestMeta.okCodes.Clone(),
-			enc:     c.defaultRequestMeta.enc,
-			dec:     c.defaultRequestMeta.dec,
+			doer:           c.defaultRequestMeta.doer,
+			headers:        c.defaultRequestMeta.headers.Clone(),
+			okCodes:        c.defaultRequestMeta.okCodes.Clone(),
+			enc:            c.defaultRequestMeta.enc,
+			dec:            c.defaultRequestMeta.dec,
+			customEnc:      c.defaultRequestMeta.customEnc,
+			customDec:      c.defaultRequestMeta.customDec,
+			streamDec:      c.defaultRequestMeta.streamDec,
+			before:         append([]RequestFunc(nil), c.defaultRequestMeta.before...),
+			after:          append([]ResponseFunc(nil), c.defaultRequestMeta.after...),
+			ctxSink:        c.defaultRequestMeta.ctxSink,
+			retry:          c.defaultRequestMeta.retry,
+			baseURL:        c.defaultRequestMeta.baseURL,
+			query:          cloneQuery(c.defaultRequestMeta.query),
+			pathParams:     clonePathParams(c.defaultRequestMeta.pathParams),
+			codecs:         cloneCodecs(c.defaultRequestMeta.codecs),
+			autoDecompress: c.defaultRequestMeta.autoDecompress,
+			decompressors:  cloneDecompressors(c.defaultRequestMeta.decompressors),
 		}
 
 		for _, o := range opt {
@@ -375,31 +545,96 @@ func (c Client) request(ctx context.Context, url, method string, payload,
 		}
 	}
 
-	buf, err := buildRequestBody(rm.enc, payload)
+	reqURL, err := buildRequestURL(url, rm.baseURL, rm.pathParams, rm.query)
 	if err != nil {
 		return fmt.Errorf("%s:request:%w", c.name, err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, url, buf)
+	enc := resolveEncoder(rm, rm.headers.Get("Content-Type"))
+
+	buf, err := buildRequestBody(ctx, enc, payload)
 	if err != nil {
 		return fmt.Errorf("%s:request:%w", c.name, err)
 	}
 
-	req.Header = rm.headers
+	body := buf.Bytes()
 
-	resp, err := c.do(req, rm)
-	if err != nil {
-		return fmt.Errorf("%s:request:%w", c.name, err)
+	attempts := 1
+	if rm.retry != nil && rm.retry.MaxAttempts > attempts {
+		attempts = rm.retry.MaxAttempts
 	}
 
-	defer resp.Body.Close()
+	var lastErr error
 
-	err = parseResponseBody(rm.dec, resp.Body, result)
-	if err != nil {
-		return fmt.Errorf("%s:request:%w", c.name, err)
+	for attempt := 1; attempt <= attempts; attempt++ {
+		req, reqErr := http.NewRequestWithContext(ctx, method, reqURL, bytes.NewReader(body))
+		if reqErr != nil {
+			return fmt.Errorf("%s:request:%w", c.name, reqErr)
+		}
+
+		req.Header = rm.headers.Clone()
+		req.GetBody = func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(body)), nil
+		}
+
+		if rm.autoDecompress && req.Header.Get("Accept-Encoding") == "" {
+			req.Header.Set("Accept-Encoding", "gzip, deflate")
+		}
+
+		hctx, resp, sendErr := c.send(ctx, req, rm)
+		if rm.ctxSink != nil {
+			*rm.ctxSink = hctx
+		}
+
+		if sendErr == nil {
+			if err := decompressBody(rm, resp); err != nil {
+				_ = resp.Body.Close()
+
+				return fmt.Errorf("%s:request:%w", c.name, err)
+			}
+		}
+
+		if sendErr == nil && rm.okCodes[resp.StatusCode] {
+			if rm.streamDec != nil {
+				if err := rm.streamDec(hctx, resp, result); err != nil {
+					return fmt.Errorf("%s:request:%w", c.name, err)
+				}
+
+				return nil
+			}
+
+			defer resp.Body.Close()
+
+			dec := resolveDecoder(rm, resp.Header.Get("Content-Type"))
+
+			if err := parseResponseBody(hctx, dec, resp.Body, result); err != nil {
+				return fmt.Errorf("%s:request:%w", c.name, err)
+			}
+
+			return nil
+		}
+
+		if sendErr != nil {
+			lastErr = sendErr
+		} else {
+			lastErr = buildStatusError(resp)
+		}
+
+		retryable := rm.retry != nil && attempt < attempts && rm.retry.shouldRetry(resp, sendErr)
+		if !retryable {
+			break
+		}
+
+		delay := rm.retry.delay(attempt, resp)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s:request:%w", c.name, ctx.Err())
+		case <-time.After(delay):
+		}
 	}
 
-	return nil
+	return fmt.Errorf("%s:request:%w", c.name, lastErr)
 }
 
 // Get send get request
@@ -12,9 +12,10 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"reflect"
-	"runtime"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func Test_Error(t *testing.T) {
@@ -123,7 +124,7 @@ func Test_buildRequestBody(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := buildRequestBody(tt.args.enc, tt.args.payload)
+			got, err := buildRequestBody(context.Background(), adaptEncoder(tt.args.enc), tt.args.payload)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("buildRequestBody() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -218,7 +219,7 @@ func Test_parseResponse(t *testing.T) {
 			switch tt.args.resultType {
 			case byts:
 				var result []byte
-				err = parseResponseBody(BytesDecoder, &buf, &result)
+				err = parseResponseBody(context.Background(), adaptDecoder(BytesDecoder), &buf, &result)
 
 				if (err != nil) != tt.wantErr {
 					t.Errorf("parseResponseBody() error = %v, wantErr %v", err, tt.wantErr)
@@ -235,7 +236,7 @@ func Test_parseResponse(t *testing.T) {
 
 				var result resultTp
 
-				err = parseResponseBody(JSONDecoder, &buf, &result)
+				err = parseResponseBody(context.Background(), adaptDecoder(JSONDecoder), &buf, &result)
 
 				if tt.wantErr {
 					if err == nil {
@@ -260,7 +261,7 @@ func Test_parseResponse(t *testing.T) {
 			case form:
 				var result url.Values
 
-				err = parseResponseBody(FormURLEncodedDecoder, &buf, &result)
+				err = parseResponseBody(context.Background(), adaptDecoder(FormURLEncodedDecoder), &buf, &result)
 				if tt.wantErr {
 					if err == nil {
 						t.Errorf("parseResponseBody() wantErr %v but error empty", tt.wantErr)
@@ -280,7 +281,7 @@ func Test_parseResponse(t *testing.T) {
 					t.Errorf("parseResponseBody() result = %v, expected %v", result, expected)
 				}
 			case empty:
-				err = parseResponseBody(FormURLEncodedDecoder, &buf, nil)
+				err = parseResponseBody(context.Background(), adaptDecoder(FormURLEncodedDecoder), &buf, nil)
 				if tt.wantErr {
 					if err == nil {
 						t.Errorf("parseResponseBody() wantErr %v but error empty", tt.wantErr)
@@ -292,10 +293,6 @@ func Test_parseResponse(t *testing.T) {
 	}
 }
 
-func checkFuncName(v interface{}, name string) bool {
-	return strings.HasSuffix(runtime.FuncForPC(reflect.ValueOf(v).Pointer()).Name(), name)
-}
-
 func Test_requestOptions(t *testing.T) {
 	cli := New("test", WithDoer(nil))
 	rmt := cli.defaultRequestMeta
@@ -315,13 +312,21 @@ func Test_requestOptions(t *testing.T) {
 		t.FailNow()
 	}
 
-	if !checkFuncName(rmt.dec, "JSONDecoder") {
-		t.Errorf(`!checkFuncName(rmt.dec, "JSONDecoder")`)
+	var decoded struct {
+		A int `json:"a"`
+	}
+
+	if err := rmt.dec(context.Background(), strings.NewReader(`{"a":1}`), &decoded); err != nil || decoded.A != 1 {
+		t.Errorf("rmt.dec should default to JSONDecoder, got err=%v decoded=%v", err, decoded)
 		t.FailNow()
 	}
 
-	if !checkFuncName(rmt.enc, "JSONEncoder") {
-		t.Errorf(`!checkFuncName(rmt.enc, "JSONEncoder")`)
+	var encoded bytes.Buffer
+
+	if err := rmt.enc(context.Background(), &encoded, struct {
+		A int `json:"a"`
+	}{A: 1}); err != nil || encoded.String() != "{\"a\":1}\n" {
+		t.Errorf(`rmt.enc should default to JSONEncoder, got err=%v encoded=%q`, err, encoded.String())
 		t.FailNow()
 	}
 
@@ -348,13 +353,17 @@ func Test_requestOptions(t *testing.T) {
 		t.FailNow()
 	}
 
-	if !checkFuncName(rmt.dec, "BytesDecoder") {
-		t.Errorf(`!checkFuncName(rmt.dec, "BytesDecoder")`)
+	var decodedBytes []byte
+
+	if err := rmt.dec(context.Background(), strings.NewReader("raw"), &decodedBytes); err != nil || string(decodedBytes) != "raw" {
+		t.Errorf("rmt.dec should be BytesDecoder after WithDecoder, got err=%v decoded=%q", err, decodedBytes)
 		t.FailNow()
 	}
 
-	if !checkFuncName(rmt.enc, "BytesEncoder") {
-		t.Errorf(`!checkFuncName(rmt.enc, "BytesEncoder")`)
+	var encodedBytes bytes.Buffer
+
+	if err := rmt.enc(context.Background(), &encodedBytes, []byte("raw")); err != nil || encodedBytes.String() != "raw" {
+		t.Errorf("rmt.enc should be BytesEncoder after WithEncoder, got err=%v encoded=%q", err, encodedBytes.String())
 		t.FailNow()
 	}
 
@@ -367,6 +376,8 @@ func Test_requestOptions(t *testing.T) {
 
 func TestClient_request(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+
 		if req.URL.Query().Get("err") != "1" {
 			rw.WriteHeader(http.StatusOK)
 		} else {
@@ -501,3 +512,172 @@ func TestClient_request(t *testing.T) {
 		})
 	}
 }
+
+type ctxKey string
+
+func TestClient_requestHooks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("x-trace-id", "abc")
+		rw.Header().Set("Content-Type", "application/json")
+		rw.WriteHeader(http.StatusOK)
+		_, _ = rw.Write([]byte(`{}`))
+	}))
+
+	defer server.Close()
+
+	var sink context.Context
+
+	before := func(ctx context.Context, req *http.Request) context.Context {
+		req.Header.Set("x-auth", "token")
+		return context.WithValue(ctx, ctxKey("before"), "1")
+	}
+
+	after := func(ctx context.Context, resp *http.Response) context.Context {
+		return context.WithValue(ctx, ctxKey("trace-id"), resp.Header.Get("x-trace-id"))
+	}
+
+	cli := New("test", WithDoer(server.Client()))
+
+	var result map[string]interface{}
+
+	err := cli.Get(context.Background(), server.URL, &result,
+		WithBefore(before), WithAfter(after), WithContextSink(&sink))
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+
+	if sink == nil {
+		t.Errorf("sink should not be nil")
+		t.FailNow()
+	}
+
+	if sink.Value(ctxKey("before")) != "1" {
+		t.Errorf("sink should carry before hook value")
+	}
+
+	if sink.Value(ctxKey("trace-id")) != "abc" {
+		t.Errorf("sink should carry after hook value, got %v", sink.Value(ctxKey("trace-id")))
+	}
+}
+
+func TestClient_requestRetry(t *testing.T) {
+	var attempts int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		data, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			panic(err)
+		}
+
+		n := atomic.AddInt32(&attempts, 1)
+
+		if n < 3 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		if string(data) != "{\"a\":1}\n" {
+			t.Errorf("body was not rewound between retries, got %q", data)
+		}
+
+		rw.Header().Set("Content-Type", "application/json")
+		rw.WriteHeader(http.StatusOK)
+		_, _ = rw.Write([]byte(`{}`))
+	}))
+
+	defer server.Close()
+
+	cli := New("test", WithDoer(server.Client()), WithRetry(RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    10 * time.Millisecond,
+		Factor:      2,
+		RetryableStatusCodes: codeSet{
+			http.StatusServiceUnavailable: true,
+		},
+	}))
+
+	var result map[string]interface{}
+
+	err := cli.Post(context.Background(), server.URL, map[string]interface{}{"a": 1}, &result)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestClient_requestRetryDefaultsStatusCodesAndFactor(t *testing.T) {
+	var attempts int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		n := atomic.AddInt32(&attempts, 1)
+
+		if n < 3 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		rw.Header().Set("Content-Type", "application/json")
+		rw.WriteHeader(http.StatusOK)
+		_, _ = rw.Write([]byte(`{}`))
+	}))
+
+	defer server.Close()
+
+	cli := New("test", WithDoer(server.Client()), WithRetry(RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+	}))
+
+	var result map[string]interface{}
+
+	err := cli.Get(context.Background(), server.URL, &result)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts via the default retryable status codes, got %d", attempts)
+	}
+}
+
+func TestClient_requestRetryExhausted(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+
+	defer server.Close()
+
+	cli := New("test", WithDoer(server.Client()), WithRetry(RetryPolicy{
+		MaxAttempts: 2,
+		BaseDelay:   time.Millisecond,
+		RetryableStatusCodes: codeSet{
+			http.StatusServiceUnavailable: true,
+		},
+	}))
+
+	var result map[string]interface{}
+
+	err := cli.Get(context.Background(), server.URL, &result)
+	if err == nil {
+		t.Error("request() want error but its nil")
+		t.FailNow()
+	}
+
+	var gotErr ErrWithResponseData
+
+	if !errors.As(err, &gotErr) {
+		t.Errorf("request() error should be ErrWithResponseData")
+		t.FailNow()
+	}
+
+	if gotErr.Code() != http.StatusServiceUnavailable {
+		t.Errorf("gotErr.Code() = %d, want %d", gotErr.Code(), http.StatusServiceUnavailable)
+	}
+}
@@ -0,0 +1,197 @@
+package httpcli
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"sync"
+)
+
+// Codec bundles an EncoderFunc and a DecoderFunc for a given content type.
+type Codec struct {
+	ContentType string
+	Encoder     EncoderFunc
+	Decoder     DecoderFunc
+}
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[string]Codec{}
+)
+
+// RegisterCodec registers c globally for c.ContentType so every Client,
+// including ones already constructed, can encode/decode it automatically.
+// It mirrors stdlib registries such as image.RegisterFormat; call it from
+// an init function in a package that adds support for a new content type
+// (e.g. zstd, brotli, protobuf).
+func RegisterCodec(c Codec) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+
+	codecs[c.ContentType] = c
+}
+
+func lookupCodec(contentType string) (Codec, bool) {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mt = contentType
+	}
+
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+
+	c, ok := codecs[mt]
+
+	return c, ok
+}
+
+func init() {
+	RegisterCodec(Codec{
+		ContentType: "application/json",
+		Encoder:     adaptEncoder(JSONEncoder), Decoder: adaptDecoder(JSONDecoder),
+	})
+	RegisterCodec(Codec{
+		ContentType: "application/x-www-form-urlencoded",
+		Encoder:     adaptEncoder(FormURLEncodedEncoder), Decoder: adaptDecoder(FormURLEncodedDecoder),
+	})
+	RegisterCodec(Codec{
+		ContentType: "application/octet-stream",
+		Encoder:     adaptEncoder(BytesEncoder), Decoder: adaptDecoder(BytesDecoder),
+	})
+	RegisterCodec(Codec{
+		ContentType: "application/xml",
+		Encoder:     adaptEncoder(XMLEncoder), Decoder: adaptDecoder(XMLDecoder),
+	})
+	RegisterCodec(Codec{
+		ContentType: "text/plain",
+		Encoder:     adaptEncoder(StringEncoder), Decoder: adaptDecoder(StringDecoder),
+	})
+}
+
+// XMLDecoder decode data from r to v as xml
+func XMLDecoder(r io.Reader, v interface{}) error {
+	if err := xml.NewDecoder(r).Decode(v); err != nil {
+		return fmt.Errorf("httpcli:XMLDecoder:%w", err)
+	}
+
+	return nil
+}
+
+// XMLEncoder encode data from v to w as xml
+func XMLEncoder(w io.Writer, v interface{}) error {
+	if err := xml.NewEncoder(w).Encode(v); err != nil {
+		return fmt.Errorf("httpcli:XMLEncoder:%w", err)
+	}
+
+	return nil
+}
+
+// StringDecoder no actually do any decode operation instead it just copies
+// body from reader r into string v (v must be a *string)
+func StringDecoder(r io.Reader, v interface{}) error {
+	s := v.(*string)
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("httpcli:StringDecoder:%w", err)
+	}
+
+	*s = string(data)
+
+	return nil
+}
+
+// StringEncoder no actually do any encode operation instead it just writes
+// string v to writer w
+func StringEncoder(w io.Writer, v interface{}) error {
+	if _, err := io.Copy(w, bytes.NewBufferString(v.(string))); err != nil {
+		return fmt.Errorf("httpcli:StringEncoder:%w", err)
+	}
+
+	return nil
+}
+
+// WithCodec request option registers c in the request's own codec table,
+// taking precedence over the globally registered codec for c.ContentType.
+func WithCodec(c Codec) RequestOption {
+	return func(meta *requestMeta) {
+		if meta.codecs == nil {
+			meta.codecs = make(map[string]Codec, 1)
+		}
+
+		meta.codecs[c.ContentType] = c
+	}
+}
+
+func cloneCodecs(m map[string]Codec) map[string]Codec {
+	if m == nil {
+		return nil
+	}
+
+	newM := make(map[string]Codec, len(m))
+
+	for k, v := range m {
+		newM[k] = v
+	}
+
+	return newM
+}
+
+func stripContentTypeParams(contentType string) string {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType
+	}
+
+	return mt
+}
+
+// resolveEncoder picks the EncoderFunc registered for the request's
+// outgoing Content-Type, falling back to rm.enc (set via WithEncoderFunc/
+// WithEncoder, or JSONEncoder by default) when no codec is registered for it.
+// An explicit WithEncoder/WithEncoderFunc override always wins over the
+// registry - callers reach for those options specifically to bypass the
+// content-type-driven default, so the registry must not shadow them.
+func resolveEncoder(rm requestMeta, contentType string) EncoderFunc {
+	if rm.customEnc {
+		return rm.enc
+	}
+
+	ct := stripContentTypeParams(contentType)
+
+	if c, ok := rm.codecs[ct]; ok && c.Encoder != nil {
+		return c.Encoder
+	}
+
+	if c, ok := lookupCodec(ct); ok && c.Encoder != nil {
+		return c.Encoder
+	}
+
+	return rm.enc
+}
+
+// resolveDecoder picks the DecoderFunc registered for the response's
+// Content-Type, falling back to rm.dec (set via WithDecoderFunc/WithDecoder,
+// or JSONDecoder by default) when no codec is registered for it.
+// An explicit WithDecoder/WithDecoderFunc override always wins over the
+// registry - see resolveEncoder.
+func resolveDecoder(rm requestMeta, contentType string) DecoderFunc {
+	if rm.customDec {
+		return rm.dec
+	}
+
+	ct := stripContentTypeParams(contentType)
+
+	if c, ok := rm.codecs[ct]; ok && c.Decoder != nil {
+		return c.Decoder
+	}
+
+	if c, ok := lookupCodec(ct); ok && c.Decoder != nil {
+		return c.Decoder
+	}
+
+	return rm.dec
+}
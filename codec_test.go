@@ -0,0 +1,120 @@
+package httpcli
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func Test_resolveEncoderDecoder(t *testing.T) {
+	rm := requestMeta{enc: adaptEncoder(JSONEncoder), dec: adaptDecoder(JSONDecoder)}
+
+	var xmlBuf bytes.Buffer
+
+	type xmlPayload struct {
+		A int `xml:"a"`
+	}
+
+	if err := resolveEncoder(rm, "application/xml")(context.Background(), &xmlBuf, xmlPayload{A: 1}); err != nil ||
+		!strings.Contains(xmlBuf.String(), "<a>1</a>") {
+		t.Errorf("resolveEncoder() should pick the registered XML encoder, got err=%v buf=%q", err, xmlBuf.String())
+	}
+
+	var str string
+
+	if err := resolveDecoder(rm, "text/plain; charset=utf-8")(context.Background(),
+		strings.NewReader("hi"), &str); err != nil || str != "hi" {
+		t.Errorf("resolveDecoder() should pick the registered text decoder and strip charset params, got err=%v str=%q",
+			err, str)
+	}
+
+	var jsonBuf bytes.Buffer
+
+	if err := resolveEncoder(rm, "application/unknown")(context.Background(), &jsonBuf,
+		struct {
+			A int `json:"a"`
+		}{A: 1}); err != nil || jsonBuf.String() != "{\"a\":1}\n" {
+		t.Errorf("resolveEncoder() should fall back to rm.enc for an unregistered content type, got err=%v buf=%q",
+			err, jsonBuf.String())
+	}
+
+	WithCodec(Codec{
+		ContentType: "application/json",
+		Encoder:     adaptEncoder(BytesEncoder), Decoder: adaptDecoder(BytesDecoder),
+	})(&rm)
+
+	var bytesBuf bytes.Buffer
+
+	if err := resolveEncoder(rm, "application/json")(context.Background(), &bytesBuf, []byte("raw")); err != nil ||
+		bytesBuf.String() != "raw" {
+		t.Errorf("resolveEncoder() should prefer a request-scoped codec over the global registry, got err=%v buf=%q",
+			err, bytesBuf.String())
+	}
+}
+
+func Test_resolveEncoderDecoder_explicitOverrideBeatsRegistry(t *testing.T) {
+	var encoded bytes.Buffer
+
+	customEnc := func(_ context.Context, w io.Writer, v interface{}) error {
+		_, err := w.Write([]byte("custom:" + v.(string)))
+
+		return err
+	}
+
+	customDec := func(_ context.Context, _ io.Reader, v interface{}) error {
+		*v.(*string) = "custom"
+
+		return nil
+	}
+
+	var rm requestMeta
+
+	WithEncoderFunc(customEnc)(&rm)
+	WithDecoderFunc(customDec)(&rm)
+
+	if err := resolveEncoder(rm, "application/json")(context.Background(), &encoded, "x"); err != nil ||
+		encoded.String() != "custom:x" {
+		t.Errorf("resolveEncoder() should prefer an explicit WithEncoder/WithEncoderFunc override over the global "+
+			"registry, got err=%v buf=%q", err, encoded.String())
+	}
+
+	var decoded string
+
+	if err := resolveDecoder(rm, "application/json")(context.Background(), strings.NewReader("ignored"),
+		&decoded); err != nil || decoded != "custom" {
+		t.Errorf("resolveDecoder() should prefer an explicit WithDecoder/WithDecoderFunc override over the global "+
+			"registry, got err=%v str=%q", err, decoded)
+	}
+}
+
+func TestClient_requestCodecNegotiation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/xml")
+		rw.WriteHeader(http.StatusOK)
+		_, _ = rw.Write([]byte(`<resp><a>1</a></resp>`))
+	}))
+
+	defer server.Close()
+
+	cli := New("test", WithDoer(server.Client()))
+
+	type xmlResp struct {
+		A int `xml:"a"`
+	}
+
+	var result xmlResp
+
+	err := cli.Get(context.Background(), server.URL, &result)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+
+	if result.A != 1 {
+		t.Errorf("result.A = %d, want 1", result.A)
+	}
+}
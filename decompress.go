@@ -0,0 +1,129 @@
+package httpcli
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Decompressor decodes a compressed response body given the raw compressed
+// stream, as selected by the response's Content-Encoding header.
+type Decompressor func(io.Reader) (io.ReadCloser, error)
+
+var builtinDecompressors = map[string]Decompressor{
+	"gzip": func(r io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(r)
+	},
+	"deflate": func(r io.Reader) (io.ReadCloser, error) {
+		return flate.NewReader(r), nil
+	},
+}
+
+// WithAutoDecompress request option enables or disables transparent
+// gzip/deflate response decompression. When enabled it also sets
+// "Accept-Encoding: gzip, deflate" on outgoing requests that don't already
+// set their own.
+func WithAutoDecompress(v bool) RequestOption {
+	return func(meta *requestMeta) {
+		meta.autoDecompress = v
+	}
+}
+
+// WithDecompressor request option registers fn as the decompressor for the
+// given Content-Encoding value, overriding the built-in gzip/deflate
+// handling or adding support for another one (e.g. zstd, br) without
+// pulling the dependency into the core module.
+func WithDecompressor(encoding string, fn Decompressor) RequestOption {
+	return func(meta *requestMeta) {
+		if meta.decompressors == nil {
+			meta.decompressors = make(map[string]Decompressor, 1)
+		}
+
+		meta.decompressors[encoding] = fn
+	}
+}
+
+func lookupDecompressor(rm requestMeta, encoding string) Decompressor {
+	if fn, ok := rm.decompressors[encoding]; ok {
+		return fn
+	}
+
+	return builtinDecompressors[encoding]
+}
+
+func cloneDecompressors(m map[string]Decompressor) map[string]Decompressor {
+	if m == nil {
+		return nil
+	}
+
+	newM := make(map[string]Decompressor, len(m))
+
+	for k, v := range m {
+		newM[k] = v
+	}
+
+	return newM
+}
+
+// decompressBody, when rm.autoDecompress is set and resp carries a known
+// Content-Encoding, replaces resp.Body with a reader that transparently
+// decompresses it and clears the now-stale Content-Encoding/Content-Length
+// headers. It is a no-op for an empty or already-decompressed body (no
+// Content-Encoding left to act on, e.g. the transport did it already).
+func decompressBody(rm requestMeta, resp *http.Response) error {
+	if !rm.autoDecompress {
+		return nil
+	}
+
+	enc := strings.TrimSpace(strings.ToLower(resp.Header.Get("Content-Encoding")))
+	if enc == "" || enc == "identity" {
+		return nil
+	}
+
+	fn := lookupDecompressor(rm, enc)
+	if fn == nil {
+		return nil
+	}
+
+	rc, err := fn(resp.Body)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		return fmt.Errorf("decompress:%w", err)
+	}
+
+	resp.Body = &decompressedBody{decoded: rc, raw: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+
+	return nil
+}
+
+// decompressedBody closes both the decompressor and the raw response body
+// it wraps; closing a compress/gzip or compress/flate reader does not close
+// its underlying source.
+type decompressedBody struct {
+	decoded io.ReadCloser
+	raw     io.ReadCloser
+}
+
+func (b *decompressedBody) Read(p []byte) (int, error) {
+	return b.decoded.Read(p)
+}
+
+func (b *decompressedBody) Close() error {
+	err := b.decoded.Close()
+
+	if rawErr := b.raw.Close(); err == nil {
+		err = rawErr
+	}
+
+	return err
+}
@@ -0,0 +1,123 @@
+package httpcli
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_requestAutoDecompress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Accept-Encoding") != "gzip, deflate" {
+			t.Errorf("Accept-Encoding = %q, want %q", req.Header.Get("Accept-Encoding"), "gzip, deflate")
+		}
+
+		var buf bytes.Buffer
+
+		gw := gzip.NewWriter(&buf)
+		_, _ = gw.Write([]byte(`{"a":1}`))
+		_ = gw.Close()
+
+		rw.Header().Set("Content-Type", "application/json")
+		rw.Header().Set("Content-Encoding", "gzip")
+		rw.WriteHeader(http.StatusOK)
+		_, _ = rw.Write(buf.Bytes())
+	}))
+
+	defer server.Close()
+
+	cli := NewWithDefaults("test", WithDoer(server.Client()))
+
+	var result map[string]interface{}
+
+	err := cli.Get(context.Background(), server.URL, &result)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+
+	if result["a"] != float64(1) {
+		t.Errorf("result = %v, want map[a:1]", result)
+	}
+}
+
+type closeTrackingBody struct {
+	io.ReadCloser
+	closed bool
+}
+
+func (b *closeTrackingBody) Close() error {
+	b.closed = true
+
+	return b.ReadCloser.Close()
+}
+
+type closeTrackingRoundTripper struct {
+	http.RoundTripper
+	body *closeTrackingBody
+}
+
+func (rt *closeTrackingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := rt.RoundTripper.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	rt.body = &closeTrackingBody{ReadCloser: resp.Body}
+	resp.Body = rt.body
+
+	return resp, nil
+}
+
+func TestClient_requestAutoDecompressMalformedClosesBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		rw.Header().Set("Content-Encoding", "gzip")
+		rw.WriteHeader(http.StatusOK)
+		_, _ = rw.Write([]byte("not actually gzip"))
+	}))
+
+	defer server.Close()
+
+	httpCli := server.Client()
+	rt := &closeTrackingRoundTripper{RoundTripper: httpCli.Transport}
+	httpCli.Transport = rt
+
+	cli := NewWithDefaults("test", WithDoer(httpCli))
+
+	var result map[string]interface{}
+
+	if err := cli.Get(context.Background(), server.URL, &result); err == nil {
+		t.Error("Client.request() should return an error for a malformed gzip body")
+	}
+
+	if rt.body == nil || !rt.body.closed {
+		t.Errorf("Client.request() should close resp.Body when decompressBody fails, closed=%v", rt.body != nil && rt.body.closed)
+	}
+}
+
+func TestClient_requestAutoDecompressDisabledByDefault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Accept-Encoding") == "gzip, deflate" {
+			t.Errorf("New() should not enable auto decompress by default")
+		}
+
+		rw.Header().Set("Content-Type", "application/json")
+		rw.WriteHeader(http.StatusOK)
+		_, _ = rw.Write([]byte(`{}`))
+	}))
+
+	defer server.Close()
+
+	cli := New("test", WithDoer(server.Client()))
+
+	var result map[string]interface{}
+
+	if err := cli.Get(context.Background(), server.URL, &result); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,151 @@
+package httpcli
+
+import (
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy configures the retry/backoff behaviour applied by Client.request.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry.
+	BaseDelay time.Duration
+	// MaxDelay caps the computed backoff delay.
+	MaxDelay time.Duration
+	// Factor is the exponential backoff multiplier applied after each attempt.
+	Factor float64
+	// Jitter is the fraction (0..1) of the computed delay to randomize.
+	Jitter float64
+	// RetryableStatusCodes lists the status codes that trigger a retry.
+	RetryableStatusCodes codeSet
+	// RetryOn, when set, overrides the default retry decision. When the Doer
+	// itself fails, resp is nil and err is its error; when the response has
+	// an unexpected status code, err is nil and resp's body is already closed.
+	RetryOn func(resp *http.Response, err error) bool
+}
+
+// DefaultRetryPolicy returns the policy used when WithRetry is not given
+// explicit retryable status codes or a backoff shape: 3 attempts, 200ms base
+// delay, 5s max delay, factor 2 with 20% jitter, retrying on 429, 502, 503
+// and 504.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   200 * time.Millisecond,
+		MaxDelay:    5 * time.Second,
+		Factor:      2,
+		Jitter:      0.2,
+		RetryableStatusCodes: codeSet{
+			http.StatusTooManyRequests:    true,
+			http.StatusBadGateway:         true,
+			http.StatusServiceUnavailable: true,
+			http.StatusGatewayTimeout:     true,
+		},
+	}
+}
+
+// WithRetry request option installs a retry policy for the request. Passed
+// to New it becomes the default policy for the whole client. Any of
+// MaxAttempts, BaseDelay, MaxDelay, Factor and RetryableStatusCodes left at
+// their zero value are filled in from DefaultRetryPolicy.
+func WithRetry(p RetryPolicy) RequestOption {
+	p = p.withDefaults()
+
+	return func(meta *requestMeta) {
+		meta.retry = &p
+	}
+}
+
+// withDefaults fills MaxAttempts, BaseDelay, MaxDelay, Factor and
+// RetryableStatusCodes from DefaultRetryPolicy wherever p leaves them at
+// their zero value, so a caller can set only the fields they care about
+// (e.g. just RetryableStatusCodes, or just MaxAttempts/BaseDelay) and get a
+// sane backoff shape for the rest. Jitter is left alone since 0 is a valid
+// "no jitter" choice.
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	d := DefaultRetryPolicy()
+
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = d.MaxAttempts
+	}
+
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = d.BaseDelay
+	}
+
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = d.MaxDelay
+	}
+
+	if p.Factor <= 0 {
+		p.Factor = d.Factor
+	}
+
+	if p.RetryableStatusCodes == nil {
+		p.RetryableStatusCodes = d.RetryableStatusCodes
+	}
+
+	return p
+}
+
+func (p *RetryPolicy) shouldRetry(resp *http.Response, err error) bool {
+	if p.RetryOn != nil {
+		return p.RetryOn(resp, err)
+	}
+
+	if err != nil {
+		return true
+	}
+
+	return p.RetryableStatusCodes[resp.StatusCode]
+}
+
+func (p *RetryPolicy) delay(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if d, ok := retryAfterDelay(resp.Header.Get("Retry-After")); ok {
+			return clampDelay(d, p.MaxDelay)
+		}
+	}
+
+	d := float64(p.BaseDelay) * math.Pow(p.Factor, float64(attempt-1))
+
+	if p.Jitter > 0 {
+		d += d * p.Jitter * (rand.Float64()*2 - 1) //nolint:gosec
+	}
+
+	return clampDelay(time.Duration(d), p.MaxDelay)
+}
+
+func clampDelay(d, max time.Duration) time.Duration {
+	if d < 0 {
+		d = 0
+	}
+
+	if max > 0 && d > max {
+		return max
+	}
+
+	return d
+}
+
+// retryAfterDelay parses the Retry-After header in both its delta-seconds
+// and HTTP-date forms.
+func retryAfterDelay(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t), true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,51 @@
+package httpcli
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_requestStreamDecoder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+		_, _ = rw.Write([]byte("one\ntwo\nthree\n"))
+	}))
+
+	defer server.Close()
+
+	var lines []string
+
+	streamDec := func(_ context.Context, resp *http.Response, result interface{}) error {
+		defer resp.Body.Close()
+
+		out := result.(*[]string)
+
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			*out = append(*out, scanner.Text())
+		}
+
+		return scanner.Err()
+	}
+
+	cli := New("test", WithDoer(server.Client()), WithStreamDecoder(streamDec))
+
+	if err := cli.Get(context.Background(), server.URL, &lines); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+
+	want := []string{"one", "two", "three"}
+	if len(lines) != len(want) {
+		t.Fatalf("lines = %v, want %v", lines, want)
+	}
+
+	for i, l := range want {
+		if lines[i] != l {
+			t.Errorf("lines[%d] = %q, want %q", i, lines[i], l)
+		}
+	}
+}